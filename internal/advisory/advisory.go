@@ -0,0 +1,103 @@
+// Package advisory cross-references installed packages against upstream
+// CVE/USN feeds (the Ubuntu OVAL feed and the Debian Security Tracker) so
+// the plugin can report real vulnerability findings alongside the
+// policy-based ones.
+package advisory
+
+import (
+	"github.com/compliance-framework/plugin-apt-versions/internal/debver"
+)
+
+// Advisory is a single security advisory affecting a package, normalised
+// from whichever upstream feed it was parsed from.
+type Advisory struct {
+	Package      string
+	CVE          string
+	USN          string
+	FixedVersion string
+	Severity     string
+}
+
+// Unfixed cross-references the installed package map (as produced by a
+// internal.PackageCollector) against advisories (keyed by source package
+// name, as returned by Client.Advisories), returning every advisory that is
+// not yet resolved on this host. Versions are compared using real dpkg
+// ordering rather than string equality, so an installed `3.0.2-0ubuntu1` is
+// correctly seen as older than a fix published as `3.0.13-0ubuntu1`.
+func Unfixed(installed map[string]any, advisories map[string][]Advisory) []Advisory {
+	unfixed := make([]Advisory, 0)
+	bySource := indexBySource(installed)
+
+	for pkg, entries := range advisories {
+		binaries, ok := bySource[pkg]
+		if !ok {
+			// No binary built from this source package is installed.
+			continue
+		}
+
+		for _, info := range binaries {
+			installedVersion := debver.Version{
+				Epoch:    toInt(info["epoch"]),
+				Upstream: toString(info["upstream"]),
+				Revision: toString(info["revision"]),
+			}
+
+			for _, adv := range entries {
+				if adv.FixedVersion == "" {
+					// No fix has been published yet: still vulnerable.
+					unfixed = append(unfixed, adv)
+					continue
+				}
+
+				if debver.Compare(installedVersion, debver.Parse(adv.FixedVersion)) < 0 {
+					unfixed = append(unfixed, adv)
+				}
+			}
+		}
+	}
+
+	return unfixed
+}
+
+// indexBySource groups installed binary packages by the source package each
+// was built from, so an advisory published against a source package (as
+// both the Ubuntu OVAL and Debian Security Tracker feeds are) matches every
+// binary built from it, not just a binary that happens to share the source
+// package's name. A package with no recorded source (the rpm/apk
+// collectors don't track one, and dpkg's own binary packages frequently are
+// their own source) is indexed under its own binary name.
+func indexBySource(installed map[string]any) map[string][]map[string]any {
+	index := make(map[string][]map[string]any)
+
+	for name, raw := range installed {
+		info, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		sourceName := name
+		if source, ok := info["source"].(map[string]any); ok {
+			if n := toString(source["name"]); n != "" {
+				sourceName = n
+			}
+		}
+
+		index[sourceName] = append(index[sourceName], info)
+	}
+
+	return index
+}
+
+func toInt(v any) int {
+	if i, ok := v.(int); ok {
+		return i
+	}
+	return 0
+}
+
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
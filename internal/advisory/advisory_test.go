@@ -0,0 +1,96 @@
+package advisory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnfixed_ReportsAdvisoriesBelowFixedVersion(t *testing.T) {
+	installed := map[string]any{
+		"openssl": map[string]any{
+			"epoch":    0,
+			"upstream": "3.0.2",
+			"revision": "0ubuntu1",
+		},
+	}
+	advisories := map[string][]Advisory{
+		"openssl": {
+			{Package: "openssl", CVE: "CVE-2024-0001", FixedVersion: "3.0.13-0ubuntu1", Severity: "high"},
+		},
+	}
+
+	unfixed := Unfixed(installed, advisories)
+	assert.Len(t, unfixed, 1)
+	assert.Equal(t, "CVE-2024-0001", unfixed[0].CVE)
+}
+
+func TestUnfixed_SkipsAdvisoriesAlreadyFixed(t *testing.T) {
+	installed := map[string]any{
+		"openssl": map[string]any{
+			"epoch":    0,
+			"upstream": "3.0.13",
+			"revision": "0ubuntu1",
+		},
+	}
+	advisories := map[string][]Advisory{
+		"openssl": {
+			{Package: "openssl", CVE: "CVE-2024-0001", FixedVersion: "3.0.13-0ubuntu1", Severity: "high"},
+		},
+	}
+
+	assert.Empty(t, Unfixed(installed, advisories))
+}
+
+func TestUnfixed_SkipsPackagesNotInstalled(t *testing.T) {
+	installed := map[string]any{}
+	advisories := map[string][]Advisory{
+		"openssl": {
+			{Package: "openssl", CVE: "CVE-2024-0001", FixedVersion: "3.0.13-0ubuntu1", Severity: "high"},
+		},
+	}
+
+	assert.Empty(t, Unfixed(installed, advisories))
+}
+
+func TestUnfixed_MatchesBinaryPackageByItsSourcePackage(t *testing.T) {
+	installed := map[string]any{
+		"libssl3": map[string]any{
+			"epoch":    0,
+			"upstream": "3.0.2",
+			"revision": "0ubuntu1",
+			"source": map[string]any{
+				"name":    "openssl",
+				"version": "3.0.2-0ubuntu1",
+			},
+		},
+	}
+	advisories := map[string][]Advisory{
+		"openssl": {
+			{Package: "openssl", CVE: "CVE-2024-0001", FixedVersion: "3.0.13-0ubuntu1", Severity: "high"},
+		},
+	}
+
+	unfixed := Unfixed(installed, advisories)
+	assert.Len(t, unfixed, 1)
+	assert.Equal(t, "CVE-2024-0001", unfixed[0].CVE)
+}
+
+func TestUnfixed_NoFixedVersionMeansStillVulnerable(t *testing.T) {
+	installed := map[string]any{
+		"openssl": map[string]any{
+			"epoch":    0,
+			"upstream": "3.0.13",
+			"revision": "0ubuntu1",
+		},
+	}
+	advisories := map[string][]Advisory{
+		"openssl": {
+			{Package: "openssl", CVE: "CVE-2024-0002", FixedVersion: "", Severity: "critical"},
+		},
+	}
+
+	unfixed := Unfixed(installed, advisories)
+	assert.Len(t, unfixed, 1)
+	assert.Equal(t, "CVE-2024-0002", unfixed[0].CVE)
+}
@@ -0,0 +1,180 @@
+package advisory
+
+import (
+	"compress/bzip2"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// SourceUbuntuOVAL selects the Ubuntu OVAL feed, covering USNs for
+	// Ubuntu releases.
+	SourceUbuntuOVAL = "ubuntu-oval"
+	// SourceDebianTracker selects the Debian Security Tracker JSON feed,
+	// covering CVEs for Debian suites.
+	SourceDebianTracker = "debian-tracker"
+
+	defaultCacheDir = "/var/cache/plugin-apt-versions/advisory"
+	defaultMaxAge   = 24 * time.Hour
+)
+
+// Config controls where advisory data comes from: which upstream feed to
+// use, how long a cached copy is considered fresh, and (for air-gapped
+// hosts) a pre-downloaded file to read instead of hitting the network.
+type Config struct {
+	Source      string
+	CacheDir    string
+	MaxAge      time.Duration
+	OfflineFile string
+}
+
+// ConfigFromMap builds a Config from the plugin's Configure() config map,
+// recognising the `advisory_source`, `advisory_cache_dir`,
+// `advisory_max_age`, and `offline_advisory_file` keys. Unset keys fall
+// back to the Ubuntu OVAL feed with a 24 hour cache TTL.
+func ConfigFromMap(config map[string]string) Config {
+	cfg := Config{
+		Source:   SourceUbuntuOVAL,
+		CacheDir: defaultCacheDir,
+		MaxAge:   defaultMaxAge,
+	}
+
+	if source := config["advisory_source"]; source != "" {
+		cfg.Source = source
+	}
+	if cacheDir := config["advisory_cache_dir"]; cacheDir != "" {
+		cfg.CacheDir = cacheDir
+	}
+	if maxAge := config["advisory_max_age"]; maxAge != "" {
+		if parsed, err := time.ParseDuration(maxAge); err == nil {
+			cfg.MaxAge = parsed
+		}
+	}
+	cfg.OfflineFile = config["offline_advisory_file"]
+
+	return cfg
+}
+
+// Client fetches and caches advisory feeds, parsing them into a map of
+// package name to the advisories affecting it.
+type Client struct {
+	logger hclog.Logger
+	config Config
+}
+
+func NewClient(logger hclog.Logger, config Config) *Client {
+	return &Client{logger: logger, config: config}
+}
+
+// Advisories returns the advisory data for the configured source. codename
+// is the Ubuntu release codename (e.g. "noble") and is only required by the
+// ubuntu-oval source; it is ignored otherwise.
+func (c *Client) Advisories(codename string) (map[string][]Advisory, error) {
+	var data []byte
+	var err error
+
+	switch {
+	case c.config.OfflineFile != "":
+		data, err = os.ReadFile(c.config.OfflineFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading offline advisory file: %w", err)
+		}
+	default:
+		data, err = c.fetch(codename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch c.config.Source {
+	case SourceUbuntuOVAL:
+		return ParseUbuntuOVAL(data)
+	case SourceDebianTracker:
+		return ParseDebianTracker(data)
+	default:
+		return nil, fmt.Errorf("unknown advisory_source %q, expected one of %s, %s", c.config.Source, SourceUbuntuOVAL, SourceDebianTracker)
+	}
+}
+
+// fetch returns the feed body, preferring a fresh on-disk cache entry over a
+// network round trip.
+func (c *Client) fetch(codename string) ([]byte, error) {
+	cachePath := filepath.Join(c.config.CacheDir, c.config.Source+".json")
+
+	if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < c.config.MaxAge {
+		c.logger.Debug(fmt.Sprintf("using cached advisory feed: %s", cachePath))
+		return os.ReadFile(cachePath)
+	}
+
+	url, err := feedURL(c.config.Source, codename)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug(fmt.Sprintf("downloading advisory feed: %s", url))
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading advisory feed %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading advisory feed %s: unexpected status %s", url, resp.Status)
+	}
+
+	var reader io.Reader = resp.Body
+	if c.config.Source == SourceUbuntuOVAL {
+		reader = bzip2.NewReader(resp.Body)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading advisory feed %s: %w", url, err)
+	}
+
+	if err := os.MkdirAll(c.config.CacheDir, 0o755); err != nil {
+		c.logger.Warn(fmt.Sprintf("could not create advisory cache dir, will re-download every run: %s", err))
+	} else if err := os.WriteFile(cachePath, data, 0o644); err != nil {
+		c.logger.Warn(fmt.Sprintf("could not write advisory cache file, will re-download every run: %s", err))
+	}
+
+	return data, nil
+}
+
+func feedURL(source, codename string) (string, error) {
+	switch source {
+	case SourceUbuntuOVAL:
+		if codename == "" {
+			return "", fmt.Errorf("cannot fetch the ubuntu-oval feed without a release codename")
+		}
+		return fmt.Sprintf("https://security-metadata.canonical.com/oval/com.ubuntu.%s.usn.oval.xml.bz2", codename), nil
+	case SourceDebianTracker:
+		return "https://security-tracker.debian.org/tracker/data/json", nil
+	default:
+		return "", fmt.Errorf("unknown advisory_source %q, expected one of %s, %s", source, SourceUbuntuOVAL, SourceDebianTracker)
+	}
+}
+
+// DetectCodename reads the VERSION_CODENAME entry from /etc/os-release, the
+// release codename (e.g. "noble") the ubuntu-oval feed URL is keyed on.
+func DetectCodename() string {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, ok := strings.CutPrefix(line, "VERSION_CODENAME="); ok {
+			return strings.Trim(name, "\"")
+		}
+	}
+
+	return ""
+}
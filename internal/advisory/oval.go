@@ -0,0 +1,110 @@
+package advisory
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The Ubuntu OVAL feed is documented at
+// https://security-metadata.canonical.com/oval/ . We only care about a
+// small slice of the schema: each <definition> carries a USN in its title,
+// a set of CVE references, a severity, and a tree of <criterion> elements
+// whose comments describe which package versions are affected. Package
+// dumps observed in the wild phrase the fixed-version check as
+// "<package> is earlier than <version>", which is what we match on below.
+var ovalFixedVersionPattern = regexp.MustCompile(`^(\S+) is earlier than (\S+)$`)
+
+type ovalDocument struct {
+	XMLName     xml.Name  `xml:"oval_definitions"`
+	Definitions []ovalDef `xml:"definitions>definition"`
+}
+
+type ovalDef struct {
+	Metadata ovalMetadata `xml:"metadata"`
+	Criteria ovalCriteria `xml:"criteria"`
+}
+
+type ovalMetadata struct {
+	Title      string           `xml:"title"`
+	References []ovalReference  `xml:"reference"`
+	Advisory   ovalAdvisoryMeta `xml:"advisory"`
+}
+
+type ovalReference struct {
+	RefID  string `xml:"ref_id,attr"`
+	Source string `xml:"source,attr"`
+}
+
+type ovalAdvisoryMeta struct {
+	Severity string `xml:"severity"`
+}
+
+type ovalCriteria struct {
+	Criterions []ovalCriterion `xml:"criterion"`
+	Criterias  []ovalCriteria  `xml:"criteria"`
+}
+
+type ovalCriterion struct {
+	Comment string `xml:"comment,attr"`
+}
+
+// ParseUbuntuOVAL parses a (decompressed) Ubuntu OVAL XML document into a
+// map of package name to the advisories affecting it.
+func ParseUbuntuOVAL(data []byte) (map[string][]Advisory, error) {
+	var doc ovalDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing ubuntu oval feed: %w", err)
+	}
+
+	advisories := make(map[string][]Advisory)
+
+	for _, def := range doc.Definitions {
+		usn := usnFromTitle(def.Metadata.Title)
+
+		var cves []string
+		for _, ref := range def.Metadata.References {
+			if strings.EqualFold(ref.Source, "CVE") {
+				cves = append(cves, ref.RefID)
+			}
+		}
+
+		for pkg, fixedVersion := range fixedVersionsByPackage(def.Criteria) {
+			advisories[pkg] = append(advisories[pkg], Advisory{
+				Package:      pkg,
+				CVE:          strings.Join(cves, ", "),
+				USN:          usn,
+				FixedVersion: fixedVersion,
+				Severity:     def.Metadata.Advisory.Severity,
+			})
+		}
+	}
+
+	return advisories, nil
+}
+
+func usnFromTitle(title string) string {
+	if colon := strings.Index(title, ":"); colon != -1 {
+		return title[:colon]
+	}
+	return title
+}
+
+func fixedVersionsByPackage(criteria ovalCriteria) map[string]string {
+	fixed := make(map[string]string)
+
+	for _, criterion := range criteria.Criterions {
+		if match := ovalFixedVersionPattern.FindStringSubmatch(criterion.Comment); match != nil {
+			fixed[match[1]] = match[2]
+		}
+	}
+
+	for _, nested := range criteria.Criterias {
+		for pkg, version := range fixedVersionsByPackage(nested) {
+			fixed[pkg] = version
+		}
+	}
+
+	return fixed
+}
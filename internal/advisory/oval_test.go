@@ -0,0 +1,43 @@
+package advisory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleOVAL = `<?xml version="1.0" encoding="UTF-8"?>
+<oval_definitions>
+  <definitions>
+    <definition>
+      <metadata>
+        <title>USN-6731-1: OpenSSL vulnerabilities</title>
+        <reference ref_id="CVE-2024-0001" source="CVE"/>
+        <reference ref_id="CVE-2024-0002" source="CVE"/>
+        <advisory>
+          <severity>High</severity>
+        </advisory>
+      </metadata>
+      <criteria>
+        <criteria>
+          <criterion comment="openssl is earlier than 3.0.13-0ubuntu1"/>
+        </criteria>
+      </criteria>
+    </definition>
+  </definitions>
+</oval_definitions>`
+
+func TestParseUbuntuOVAL(t *testing.T) {
+	advisories, err := ParseUbuntuOVAL([]byte(sampleOVAL))
+	assert.NoError(t, err)
+
+	entries, ok := advisories["openssl"]
+	if !ok {
+		t.Fatalf("expected an advisory entry for openssl")
+	}
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "USN-6731-1", entries[0].USN)
+	assert.Equal(t, "CVE-2024-0001, CVE-2024-0002", entries[0].CVE)
+	assert.Equal(t, "3.0.13-0ubuntu1", entries[0].FixedVersion)
+	assert.Equal(t, "High", entries[0].Severity)
+}
@@ -0,0 +1,74 @@
+package advisory
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The Debian Security Tracker JSON feed
+// (https://security-tracker.debian.org/tracker/data/json) is keyed by
+// source package name, then by CVE, with one entry per Debian suite the
+// package appears in.
+type trackerRelease struct {
+	Status       string `json:"status"`
+	FixedVersion string `json:"fixed_version"`
+	Urgency      string `json:"urgency"`
+}
+
+type trackerCVE struct {
+	Releases map[string]trackerRelease `json:"releases"`
+}
+
+// ParseDebianTracker parses the Debian Security Tracker JSON feed into a map
+// of package name to the advisories affecting it, one per suite the CVE is
+// tracked against. Suites whose status is not actionable (see
+// isResolvableVulnerability) produce no advisory.
+func ParseDebianTracker(data []byte) (map[string][]Advisory, error) {
+	var document map[string]map[string]trackerCVE
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing debian security tracker feed: %w", err)
+	}
+
+	advisories := make(map[string][]Advisory)
+
+	for pkg, cves := range document {
+		for cve, details := range cves {
+			for _, release := range details.Releases {
+				if !isResolvableVulnerability(release) {
+					// "no-dsa" (maintainers decided not to fix), "end-of-life",
+					// "ignored", "undetermined", and "resolved" with no
+					// recorded fix version are not actionable findings: skip
+					// them rather than defaulting to "vulnerable forever".
+					continue
+				}
+
+				advisories[pkg] = append(advisories[pkg], Advisory{
+					Package:      pkg,
+					CVE:          cve,
+					FixedVersion: release.FixedVersion,
+					Severity:     release.Urgency,
+				})
+			}
+		}
+	}
+
+	return advisories, nil
+}
+
+// isResolvableVulnerability reports whether a tracker release status
+// represents a genuine, resolvable vulnerability worth cross-referencing
+// against installed packages: either still "open", or "resolved" with a
+// real fixed version to compare against. Every other status Debian uses
+// ("no-dsa", "end-of-life", "ignored", "undetermined", ...) means the
+// maintainers have explicitly decided not to track this as an actionable
+// fix, so it must not be reported as unfixed.
+func isResolvableVulnerability(release trackerRelease) bool {
+	switch release.Status {
+	case "open":
+		return true
+	case "resolved":
+		return release.FixedVersion != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package advisory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleTrackerJSON = `{
+  "openssl": {
+    "CVE-2024-0001": {
+      "releases": {
+        "bookworm": {
+          "status": "open",
+          "fixed_version": "3.0.13-1~deb12u1",
+          "urgency": "medium"
+        }
+      }
+    }
+  }
+}`
+
+func TestParseDebianTracker(t *testing.T) {
+	advisories, err := ParseDebianTracker([]byte(sampleTrackerJSON))
+	assert.NoError(t, err)
+
+	entries, ok := advisories["openssl"]
+	if !ok {
+		t.Fatalf("expected an advisory entry for openssl")
+	}
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "CVE-2024-0001", entries[0].CVE)
+	assert.Equal(t, "3.0.13-1~deb12u1", entries[0].FixedVersion)
+	assert.Equal(t, "medium", entries[0].Severity)
+}
+
+func TestParseDebianTracker_ResolvedWithFixedVersionIsKept(t *testing.T) {
+	const data = `{
+		"openssl": {
+			"CVE-2024-0002": {
+				"releases": {
+					"bookworm": {
+						"status": "resolved",
+						"fixed_version": "3.0.13-1~deb12u1",
+						"urgency": "high"
+					}
+				}
+			}
+		}
+	}`
+
+	advisories, err := ParseDebianTracker([]byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, advisories["openssl"], 1)
+	assert.Equal(t, "CVE-2024-0002", advisories["openssl"][0].CVE)
+}
+
+func TestParseDebianTracker_SkipsNonActionableStatuses(t *testing.T) {
+	const data = `{
+		"openssl": {
+			"CVE-2024-0003": {
+				"releases": {
+					"no-dsa": {"status": "no-dsa", "fixed_version": "", "urgency": ""}
+				}
+			}
+		},
+		"bash": {
+			"CVE-2024-0004": {
+				"releases": {
+					"eol": {"status": "end-of-life", "fixed_version": "", "urgency": ""}
+				}
+			}
+		},
+		"coreutils": {
+			"CVE-2024-0005": {
+				"releases": {
+					"ign": {"status": "ignored", "fixed_version": "", "urgency": ""}
+				}
+			}
+		},
+		"libc6": {
+			"CVE-2024-0006": {
+				"releases": {
+					"und": {"status": "undetermined", "fixed_version": "", "urgency": ""}
+				}
+			}
+		},
+		"zlib": {
+			"CVE-2024-0007": {
+				"releases": {
+					"bookworm": {"status": "resolved", "fixed_version": "", "urgency": ""}
+				}
+			}
+		}
+	}`
+
+	advisories, err := ParseDebianTracker([]byte(data))
+	assert.NoError(t, err)
+	assert.Empty(t, advisories)
+}
@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/compliance-framework/plugin-apt-versions/internal/debver"
+	"github.com/hashicorp/go-hclog"
+)
+
+// PackageVersions is the uniform shape returned by every PackageCollector: a
+// map of package name to the details gathered about that package (at least
+// a "version" entry, plus whatever epoch/release/source information the
+// host's package manager makes available).
+type PackageVersions = map[string]any
+
+// PackageCollector gathers the list of packages installed on the host and
+// their versions. Each supported package manager (dpkg, rpm, apk, ...) has
+// its own implementation so the rest of the plugin can stay agnostic of the
+// underlying host OS.
+type PackageCollector interface {
+	// GetInstalledPackages returns the installed packages, the raw command
+	// output it was parsed from (useful for debug logging), and an error if
+	// the underlying command could not be run or parsed.
+	GetInstalledPackages() (PackageVersions, string, error)
+}
+
+// DpkgCollector collects installed packages on Debian/Ubuntu hosts using
+// dpkg-query.
+type DpkgCollector struct {
+	logger hclog.Logger
+}
+
+func NewDpkgCollector(logger hclog.Logger) *DpkgCollector {
+	return &DpkgCollector{logger: logger}
+}
+
+func (c *DpkgCollector) GetInstalledPackages() (PackageVersions, string, error) {
+	output, err := runCommand(c.logger, `dpkg-query -W -f='${Package} ${Version} ${source:Package} ${source:Version}\n'`)
+	if err != nil {
+		return nil, output, fmt.Errorf("error running dpkg-query: %w", err)
+	}
+
+	packages, _ := getPackages(c.logger, output)
+
+	// Carry the dpkg epoch/upstream/revision fields alongside the uniform
+	// release/arch fields shared with the rpm and apk collectors. source is
+	// already populated by getPackages with the real parent source package.
+	for _, info := range packages {
+		details := info.(map[string]any)
+		details["release"] = details["revision"]
+		details["arch"] = ""
+	}
+
+	return packages, output, nil
+}
+
+// RpmCollector collects installed packages on RHEL/Fedora/SUSE hosts using
+// rpm.
+type RpmCollector struct {
+	logger hclog.Logger
+}
+
+func NewRpmCollector(logger hclog.Logger) *RpmCollector {
+	return &RpmCollector{logger: logger}
+}
+
+func (c *RpmCollector) GetInstalledPackages() (PackageVersions, string, error) {
+	output, err := runCommand(c.logger, `rpm -qa --qf '%{NAME} %{EPOCH}:%{VERSION}-%{RELEASE} %{ARCH}\n'`)
+	if err != nil {
+		return nil, output, fmt.Errorf("error running rpm -qa: %w", err)
+	}
+
+	return parseRpmOutput(c.logger, output), output, nil
+}
+
+func parseRpmOutput(logger hclog.Logger, output string) PackageVersions {
+	packages := make(PackageVersions)
+
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		parts := strings.Split(line, " ")
+		if len(parts) != 3 {
+			logger.Warn(fmt.Sprintf("unexpected number of parts in rpm line, cannot process: %s", line))
+			continue
+		}
+
+		// rpm prints the literal string "(none)" for a package with no epoch.
+		version := strings.Replace(parts[1], "(none):", "", 1)
+		parsed := debver.Parse(version)
+		packages[parts[0]] = map[string]any{
+			"version":  normalizeSemver(version),
+			"epoch":    parsed.Epoch,
+			"upstream": parsed.Upstream,
+			"revision": parsed.Revision,
+			"release":  parsed.Revision,
+			"arch":     parts[2],
+			"source":   nil,
+		}
+	}
+
+	return packages
+}
+
+// ApkCollector collects installed packages on Alpine hosts using apk.
+type ApkCollector struct {
+	logger hclog.Logger
+}
+
+func NewApkCollector(logger hclog.Logger) *ApkCollector {
+	return &ApkCollector{logger: logger}
+}
+
+func (c *ApkCollector) GetInstalledPackages() (PackageVersions, string, error) {
+	output, err := runCommand(c.logger, `apk info -v`)
+	if err != nil {
+		return nil, output, fmt.Errorf("error running apk info -v: %w", err)
+	}
+
+	return parseApkOutput(c.logger, output), output, nil
+}
+
+func parseApkOutput(logger hclog.Logger, output string) PackageVersions {
+	packages := make(PackageVersions)
+
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+
+		// apk info -v prints "name-version" pairs, e.g. "musl-1.2.5-r0", where
+		// the version always starts with a digit immediately after a dash.
+		nameEnd := -1
+		for i := 0; i < len(line)-1; i++ {
+			if line[i] == '-' && line[i+1] >= '0' && line[i+1] <= '9' {
+				nameEnd = i
+			}
+		}
+		if nameEnd == -1 {
+			logger.Warn(fmt.Sprintf("unexpected format in apk info line, cannot process: %s", line))
+			continue
+		}
+
+		name := line[:nameEnd]
+		version := line[nameEnd+1:]
+		parsed := debver.Parse(version)
+		packages[name] = map[string]any{
+			"version":  normalizeSemver(version),
+			"epoch":    parsed.Epoch,
+			"upstream": parsed.Upstream,
+			"revision": parsed.Revision,
+			"release":  parsed.Revision,
+			"arch":     "",
+			"source":   nil,
+		}
+	}
+
+	return packages
+}
+
+// knownCollectors maps a package manager name to the binary used to detect
+// it on $PATH and the collector that handles it.
+var knownCollectors = []struct {
+	manager string
+	binary  string
+	new     func(hclog.Logger) PackageCollector
+}{
+	{"dpkg", "dpkg-query", func(l hclog.Logger) PackageCollector { return NewDpkgCollector(l) }},
+	{"rpm", "rpm", func(l hclog.Logger) PackageCollector { return NewRpmCollector(l) }},
+	{"apk", "apk", func(l hclog.Logger) PackageCollector { return NewApkCollector(l) }},
+}
+
+// DetectCollector picks the PackageCollector to use on this host. If
+// override is non-empty it must name one of the known package managers
+// ("dpkg", "rpm", "apk"); otherwise the first package manager binary found
+// on $PATH is used.
+func DetectCollector(logger hclog.Logger, override string) (PackageCollector, string, error) {
+	if override != "" {
+		for _, known := range knownCollectors {
+			if known.manager == override {
+				return known.new(logger), known.manager, nil
+			}
+		}
+		return nil, "", fmt.Errorf("unknown package_manager %q, expected one of dpkg, rpm, apk", override)
+	}
+
+	for _, known := range knownCollectors {
+		if _, err := exec.LookPath(known.binary); err == nil {
+			return known.new(logger), known.manager, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("could not detect a supported package manager (dpkg, rpm, apk) on $PATH")
+}
+
+func runCommand(logger hclog.Logger, command string) (string, error) {
+	logger.Debug(fmt.Sprintf("RUNNING COMMAND: %s", command))
+	cmd := exec.Command("bash", "-c", command)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			logger.Error(fmt.Sprintf("stderr: %s", stderr.String()))
+		}
+		return stdout.String(), err
+	}
+
+	if stderr.Len() > 0 {
+		logger.Warn(fmt.Sprintf("error found running command, continuing as exited successfully: %s", stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectCollector_UnknownOverride(t *testing.T) {
+	_, _, err := DetectCollector(hclog.NewNullLogger(), "pacman")
+	assert.Error(t, err)
+}
+
+func TestDetectCollector_KnownOverride(t *testing.T) {
+	collector, manager, err := DetectCollector(hclog.NewNullLogger(), "rpm")
+	assert.NoError(t, err)
+	assert.Equal(t, "rpm", manager)
+	assert.IsType(t, &RpmCollector{}, collector)
+}
+
+func TestDpkgCollector_GetInstalledPackages_IncludesSourceParentage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("dpkg-query is stubbed via a bash script, not supported on windows")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/bash\nprintf 'libssl3 3.0.2-0ubuntu1.16 openssl 3.0.2-0ubuntu1.16\\n'\n"
+	scriptPath := filepath.Join(binDir, "dpkg-query")
+	assert.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o755))
+
+	t.Setenv("PATH", fmt.Sprintf("%s:%s", binDir, os.Getenv("PATH")))
+
+	collector := NewDpkgCollector(hclog.NewNullLogger())
+	packages, _, err := collector.GetInstalledPackages()
+	assert.NoError(t, err)
+
+	libssl := packages["libssl3"].(map[string]any)
+	source := libssl["source"].(map[string]any)
+	assert.Equal(t, "openssl", source["name"])
+	assert.Equal(t, "3.0.2-0ubuntu1.16", source["version"])
+}
+
+func TestParseRpmOutput(t *testing.T) {
+	logger := hclog.NewNullLogger()
+	packages := parseRpmOutput(logger, "glibc 2:2.34-100.el9 x86_64\nbash (none):5.1.8-6.el9 x86_64\n")
+
+	glibc := packages["glibc"].(map[string]any)
+	assert.Equal(t, 2, glibc["epoch"])
+	assert.Equal(t, "2.34", glibc["upstream"])
+	assert.Equal(t, "100.el9", glibc["revision"])
+	assert.Equal(t, "100.el9", glibc["release"])
+	assert.Equal(t, "x86_64", glibc["arch"])
+
+	bash := packages["bash"].(map[string]any)
+	assert.Equal(t, 0, bash["epoch"])
+	assert.Equal(t, "5.1.8", bash["upstream"])
+	assert.Equal(t, "6.el9", bash["revision"])
+	assert.Equal(t, "6.el9", bash["release"])
+}
+
+func TestParseApkOutput(t *testing.T) {
+	logger := hclog.NewNullLogger()
+	packages := parseApkOutput(logger, "musl-1.2.5-r0\nlibssl3-3.1.4-r5\n")
+
+	musl := packages["musl"].(map[string]any)
+	assert.Equal(t, "1.2.5", musl["version"])
+	assert.Equal(t, "1.2.5", musl["upstream"])
+	assert.Equal(t, "r0", musl["revision"])
+	assert.Equal(t, "r0", musl["release"])
+
+	libssl := packages["libssl3"].(map[string]any)
+	assert.Equal(t, "3.1.4", libssl["version"])
+	assert.Equal(t, "3.1.4", libssl["upstream"])
+	assert.Equal(t, "r5", libssl["revision"])
+	assert.Equal(t, "r5", libssl["release"])
+}
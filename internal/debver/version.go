@@ -0,0 +1,154 @@
+// Package debver implements Debian-style package version parsing and
+// comparison, following the algorithm described in Debian Policy §5.6.12.
+package debver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed Debian package version, split into the three
+// components dpkg compares independently: epoch, upstream version, and
+// Debian revision.
+type Version struct {
+	Epoch    int
+	Upstream string
+	Revision string
+}
+
+// Parse splits a raw dpkg version string (e.g. "2:1.2.3-1~ubuntu1") into its
+// epoch, upstream version, and Debian revision. A missing epoch is treated
+// as 0, and a missing revision is treated as the empty string, matching
+// dpkg's own handling of native packages with no revision.
+func Parse(raw string) Version {
+	v := Version{}
+
+	rest := raw
+	if colon := strings.Index(rest, ":"); colon != -1 {
+		if epoch, err := strconv.Atoi(rest[:colon]); err == nil {
+			v.Epoch = epoch
+		}
+		rest = rest[colon+1:]
+	}
+
+	if dash := strings.LastIndex(rest, "-"); dash != -1 {
+		v.Upstream = rest[:dash]
+		v.Revision = rest[dash+1:]
+	} else {
+		v.Upstream = rest
+		v.Revision = ""
+	}
+
+	return v
+}
+
+// Compare returns -1, 0, or 1 depending on whether a sorts before, the same
+// as, or after b, following dpkg's version comparison rules: epochs compare
+// numerically, then upstream and revision each compare using the modified
+// ASCII ordering described in Debian Policy §5.6.12.
+func Compare(a, b Version) int {
+	if a.Epoch != b.Epoch {
+		if a.Epoch < b.Epoch {
+			return -1
+		}
+		return 1
+	}
+
+	if c := compareFragment(a.Upstream, b.Upstream); c != 0 {
+		return c
+	}
+
+	return compareFragment(a.Revision, b.Revision)
+}
+
+// compareFragment implements dpkg's verrevcmp: alternate between comparing
+// runs of non-digit characters (using byteOrder) and runs of digit
+// characters (numerically), until both strings are exhausted.
+func compareFragment(a, b string) int {
+	ai, bi := 0, 0
+
+	for ai < len(a) || bi < len(b) {
+		for (ai < len(a) && !isDigit(a[ai])) || (bi < len(b) && !isDigit(b[bi])) {
+			ac, bc := byteOrder(a, ai), byteOrder(b, bi)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			ai++
+			bi++
+		}
+
+		for ai < len(a) && a[ai] == '0' {
+			ai++
+		}
+		for bi < len(b) && b[bi] == '0' {
+			bi++
+		}
+
+		aStart := ai
+		for ai < len(a) && isDigit(a[ai]) {
+			ai++
+		}
+		bStart := bi
+		for bi < len(b) && isDigit(b[bi]) {
+			bi++
+		}
+
+		aDigits, bDigits := a[aStart:ai], b[bStart:bi]
+		if len(aDigits) != len(bDigits) {
+			if len(aDigits) < len(bDigits) {
+				return -1
+			}
+			return 1
+		}
+		if aDigits != bDigits {
+			if aDigits < bDigits {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// byteOrder returns the sort order of the byte at position i in s, or the
+// order of "end of string" if i is out of range. `~` sorts before the end of
+// a string, digits rank the same as the end of a string (a digit is reached
+// here when the other string is still in its non-digit run), letters sort
+// before all other non-digit characters, and everything else sorts by its
+// ASCII value above that.
+func byteOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case c == '~':
+		return -1
+	case isDigit(c):
+		return 0
+	case isAlpha(c):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,67 @@
+package debver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	v := Parse("2:1.2.3-1~ubuntu1")
+	assert.Equal(t, 2, v.Epoch)
+	assert.Equal(t, "1.2.3", v.Upstream)
+	assert.Equal(t, "1~ubuntu1", v.Revision)
+
+	v = Parse("1.2.3")
+	assert.Equal(t, 0, v.Epoch)
+	assert.Equal(t, "1.2.3", v.Upstream)
+	assert.Equal(t, "", v.Revision)
+
+	v = Parse("9.6p1-3ubuntu13.8")
+	assert.Equal(t, 0, v.Epoch)
+	assert.Equal(t, "9.6p1", v.Upstream)
+	assert.Equal(t, "3ubuntu13.8", v.Revision)
+}
+
+func TestCompare_Epoch(t *testing.T) {
+	assert.Equal(t, -1, Compare(Parse("1.0-1"), Parse("1:1.0-1")))
+	assert.Equal(t, 1, Compare(Parse("2:1.0-1"), Parse("1.0-1")))
+	assert.Equal(t, 0, Compare(Parse("0:1.0-1"), Parse("1.0-1")))
+}
+
+func TestCompare_Numeric(t *testing.T) {
+	assert.Equal(t, -1, Compare(Parse("1.2.3"), Parse("1.2.10")))
+	assert.Equal(t, 1, Compare(Parse("1.10.0"), Parse("1.2.0")))
+	assert.Equal(t, 0, Compare(Parse("1.02.3"), Parse("1.2.3")))
+}
+
+func TestCompare_TildeSortsBeforeEverything(t *testing.T) {
+	assert.Equal(t, -1, Compare(Parse("1.0~beta1"), Parse("1.0")))
+	assert.Equal(t, -1, Compare(Parse("1.0~~"), Parse("1.0~")))
+	assert.Equal(t, 1, Compare(Parse("1.0"), Parse("1.0~beta1")))
+}
+
+func TestCompare_LettersSortBeforeNonLetters(t *testing.T) {
+	assert.Equal(t, -1, Compare(Parse("1.0a"), Parse("1.0+")))
+	assert.Equal(t, 1, Compare(Parse("1.0+"), Parse("1.0a")))
+}
+
+func TestCompare_Revision(t *testing.T) {
+	assert.Equal(t, -1, Compare(Parse("1.0-1"), Parse("1.0-2")))
+	assert.Equal(t, 1, Compare(Parse("1.0-2ubuntu2"), Parse("1.0-2ubuntu1")))
+	assert.Equal(t, 0, Compare(Parse("1.0"), Parse("1.0-0")))
+}
+
+func TestCompare_DigitsRankWithEndOfString(t *testing.T) {
+	// dpkg --compare-versions "1.2" lt "1.a2" => true: once the alternating
+	// non-digit run hits a digit in "1.2", it must rank the same as running
+	// off the end of the string, not sort after the letter in "1.a2".
+	assert.Equal(t, -1, Compare(Parse("1.2"), Parse("1.a2")))
+	assert.Equal(t, 1, Compare(Parse("1.a2"), Parse("1.2")))
+}
+
+func TestCompare_RealWorldExamples(t *testing.T) {
+	assert.Equal(t, -1, Compare(Parse("3.0.2-0ubuntu1"), Parse("3.0.13-0ubuntu1")))
+	assert.Equal(t, -1, Compare(Parse("9.6p1-3ubuntu13.7"), Parse("9.6p1-3ubuntu13.8")))
+	assert.Equal(t, 0, Compare(Parse("18.10.20180917~bzr492+repack1-3.1ubuntu5"), Parse("18.10.20180917~bzr492+repack1-3.1ubuntu5")))
+}
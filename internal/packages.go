@@ -1,51 +1,19 @@
 package internal
 
 import (
-	"bytes"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/compliance-framework/agent/runner/proto"
+	"github.com/compliance-framework/plugin-apt-versions/internal/debver"
 	"github.com/hashicorp/go-hclog"
 )
 
-// GetInstalledPackages retrieves the list of installed packages in JSON format
-func GetInstalledPackages(logger hclog.Logger) (map[string]any, []*proto.Step, error) {
-	steps := make([]*proto.Step, 0)
-
-	steps = append(steps, &proto.Step{
-		Title:       "Get installed packages",
-		Description: "Get the list of installed package names and versions on the host using the `dpkg-query` command. This will be used to evaluate the versions of installed packages against the policies supplied.",
-		Remarks:     StringAddressed("`dpkg-query -W -f='${Package} ${Version}'` is used to collect the installed packages and their versions."),
-	})
-
-	command := `dpkg-query -W -f='${Package} ${Version}\n'`
-	logger.Debug(fmt.Sprintf("RUNNING COMMAND: %s", command))
-	dpkgCmd := exec.Command("bash", "-c", command)
-
-	var dpkgStdout bytes.Buffer
-	var dpkgStderr bytes.Buffer
-	dpkgCmd.Stdout = &dpkgStdout
-	dpkgCmd.Stderr = &dpkgStderr
-	if err := dpkgCmd.Run(); err != nil {
-		if dpkgStderr.Len() > 0 {
-			logger.Error(fmt.Sprintf("stderr: %s", dpkgStderr.String()))
-		}
-		return nil, steps, fmt.Errorf("error running dpkg-query: %w", err)
-	}
-
-	if dpkgStderr.Len() > 0 {
-		logger.Warn(fmt.Sprintf("error found running dpkg-query, continuing as exited successfully: %s", dpkgStderr.String()))
-	}
-
-	// Parse the output into a map
-	packages, newSteps := getPackages(logger, dpkgStdout.String())
-	steps = append(steps, newSteps...)
-
-	return packages, steps, nil
-}
-
+// getPackages parses the
+// `dpkg-query -W -f='${Package} ${Version} ${source:Package} ${source:Version}\n'`
+// output format shared by DpkgCollector into a map of package name to
+// version details, including the source (parent) package each binary was
+// built from.
 func getPackages(logger hclog.Logger, packageData string) (map[string]any, []*proto.Step) {
 	packages := make(map[string]any)
 
@@ -55,27 +23,43 @@ func getPackages(logger hclog.Logger, packageData string) (map[string]any, []*pr
 		}
 
 		parts := strings.Split(line, " ")
-		if len(parts) != 2 {
+		if len(parts) != 4 {
 			logger.Warn(fmt.Sprintf("unexpected number of parts in package line, cannot process: %s", line))
 			continue
 		}
 
 		packageName := parts[0]
-		packageVersion := getVersion(parts[1])
-
-		packages[packageName] = packageVersion
+		rawVersion := parts[1]
+		sourceName := parts[2]
+		sourceVersion := parts[3]
+		parsed := debver.Parse(rawVersion)
+
+		packages[packageName] = map[string]any{
+			"version":  normalizeSemver(rawVersion),
+			"epoch":    parsed.Epoch,
+			"upstream": parsed.Upstream,
+			"revision": parsed.Revision,
+			"source": map[string]any{
+				"name":    sourceName,
+				"version": sourceVersion,
+			},
+		}
 	}
 
 	step := &proto.Step{
-		Title:       "Retrieved all installed packages and normalised versions",
-		Description: "Retrieved all the installed packages and their versions on the host. The versions are all normalised to a standard format for comparison of the format `x.y.z` where `x`, `y` and `z` are all integers and intended to match the standard SemVer pattern of `major.minor.patch`.",
-		Remarks:     StringAddressed(fmt.Sprintf("Normalized %d package versions", len(packages))),
+		Title:       "Retrieved all installed packages and parsed versions",
+		Description: "Retrieved all the installed packages and their versions on the host, including the source (parent) package each binary was built from. Each package carries its dpkg epoch, upstream version, and Debian revision (see Debian Policy §5.6.12) alongside a normalised `x.y.z` SemVer triple, so policies can choose between a real dpkg-style comparison and a simple SemVer one.",
+		Remarks:     StringAddressed(fmt.Sprintf("Parsed %d package versions", len(packages))),
 	}
 
 	return packages, []*proto.Step{step}
 }
 
-func getVersion(version string) string {
+// normalizeSemver reduces a dpkg version string to a best-effort `x.y.z`
+// SemVer triple. It is a lossy convenience for policies that only care about
+// a rough major/minor/patch comparison; for a correct dpkg-ordering
+// comparison use the epoch/upstream/revision fields parsed by debver instead.
+func normalizeSemver(version string) string {
 	// If the version contains a colon, we'll split the string and return the version from the second part
 	if colonIndex := strings.Index(version, ":"); colonIndex != -1 {
 		version = version[colonIndex+1:]
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -8,15 +9,34 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func packageInfo(t *testing.T, packages map[string]any, name string) map[string]any {
+	t.Helper()
+	info, ok := packages[name].(map[string]any)
+	if !ok {
+		t.Fatalf("expected package %q to be present as a map[string]any", name)
+	}
+	return info
+}
+
+// selfSourced builds a dpkg-query line for a package that is its own source,
+// which is the common case and what dpkg-query reports when a binary
+// package has no distinct Source: header.
+func selfSourced(name, version string) string {
+	return fmt.Sprintf("%s %s %s %s", name, version, name, version)
+}
+
 func TestGetSimplePackage(t *testing.T) {
 	logger := hclog.New(&hclog.LoggerOptions{
 		Level:      hclog.Error,
 		JSONFormat: true,
 	})
-	packages, steps := getPackages(logger, "mycoolpackage 1.2.3\n")
+	packages, steps := getPackages(logger, selfSourced("mycoolpackage", "1.2.3")+"\n")
 
-	version := packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.3")
+	info := packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.3", info["version"])
+	assert.Equal(t, 0, info["epoch"])
+	assert.Equal(t, "1.2.3", info["upstream"])
+	assert.Equal(t, "", info["revision"])
 	assert.Len(t, steps, 1)
 }
 
@@ -25,16 +45,20 @@ func TestGetPackageWithEpochVersion(t *testing.T) {
 		Level:      hclog.Error,
 		JSONFormat: true,
 	})
-	packages, steps := getPackages(logger, "mycoolpackage 2:1.2.3\n")
+	packages, steps := getPackages(logger, selfSourced("mycoolpackage", "2:1.2.3")+"\n")
 
-	version := packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.3")
+	info := packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.3", info["version"])
+	assert.Equal(t, 2, info["epoch"])
+	assert.Equal(t, "1.2.3", info["upstream"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 24:1.2\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "24:1.2")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.0")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.0", info["version"])
+	assert.Equal(t, 24, info["epoch"])
+	assert.Equal(t, "1.2", info["upstream"])
 	assert.Len(t, steps, 1)
 }
 
@@ -43,22 +67,26 @@ func TestGetPackageWithSpecialCharactersInVersion(t *testing.T) {
 		Level:      hclog.Error,
 		JSONFormat: true,
 	})
-	packages, steps := getPackages(logger, "mycoolpackage 1.2.3-1~ubuntu1\n")
+	packages, steps := getPackages(logger, selfSourced("mycoolpackage", "1.2.3-1~ubuntu1")+"\n")
 
-	version := packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.3")
+	info := packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.3", info["version"])
+	assert.Equal(t, "1.2.3", info["upstream"])
+	assert.Equal(t, "1~ubuntu1", info["revision"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 1.2-1ubuntu1+foo\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "1.2-1ubuntu1+foo")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.0")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.0", info["version"])
+	assert.Equal(t, "1ubuntu1+foo", info["revision"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 25.2.35+ubuntu1\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "25.2.35+ubuntu1")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "25.2.35")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "25.2.35", info["version"])
+	assert.Equal(t, "25.2.35+ubuntu1", info["upstream"])
 }
 
 func TestGetPackageWithStringCharsInVersion(t *testing.T) {
@@ -66,22 +94,23 @@ func TestGetPackageWithStringCharsInVersion(t *testing.T) {
 		Level:      hclog.Error,
 		JSONFormat: true,
 	})
-	packages, steps := getPackages(logger, "mycoolpackage 1.2.3ubuntu1\n")
+	packages, steps := getPackages(logger, selfSourced("mycoolpackage", "1.2.3ubuntu1")+"\n")
 
-	version := packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.3")
+	info := packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.3", info["version"])
+	assert.Equal(t, "1.2.3ubuntu1", info["upstream"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 25.22ubuntu1\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "25.22ubuntu1")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "25.22.0")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "25.22.0", info["version"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 25.22ubuntu1.44mystring1\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "25.22ubuntu1.44mystring1")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "25.22.44")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "25.22.44", info["version"])
 	assert.Len(t, steps, 1)
 }
 
@@ -90,16 +119,16 @@ func TestGetPackageWithLeadingZeroesInVersion(t *testing.T) {
 		Level:      hclog.Error,
 		JSONFormat: true,
 	})
-	packages, steps := getPackages(logger, "mycoolpackage 01.2.3\n")
+	packages, steps := getPackages(logger, selfSourced("mycoolpackage", "01.2.3")+"\n")
 
-	version := packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.3")
+	info := packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.3", info["version"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 25.02\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "25.02")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "25.2.0")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "25.2.0", info["version"])
 	assert.Len(t, steps, 1)
 }
 
@@ -108,19 +137,58 @@ func TestGetPackageWithoutThreeNumsInVersion(t *testing.T) {
 		Level:      hclog.Error,
 		JSONFormat: true,
 	})
-	packages, steps := getPackages(logger, "mycoolpackage 1.2\n")
+	packages, steps := getPackages(logger, selfSourced("mycoolpackage", "1.2")+"\n")
 
-	version := packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "1.2.0")
+	info := packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "1.2.0", info["version"])
 	assert.Len(t, steps, 1)
 
-	packages, steps = getPackages(logger, "mycoolpackage 25.2.5.1.6\n")
+	packages, steps = getPackages(logger, selfSourced("mycoolpackage", "25.2.5.1.6")+"\n")
 
-	version = packages["mycoolpackage"].(string)
-	assert.Equal(t, version, "25.2.5")
+	info = packageInfo(t, packages, "mycoolpackage")
+	assert.Equal(t, "25.2.5", info["version"])
 	assert.Len(t, steps, 1)
 }
 
+func TestGetPackageWithDebianRevisionAndEpoch(t *testing.T) {
+	logger := hclog.New(&hclog.LoggerOptions{
+		Level:      hclog.Error,
+		JSONFormat: true,
+	})
+	packages, _ := getPackages(logger, selfSourced("openssh-server", "1:9.6p1-3ubuntu13.8")+"\n")
+
+	info := packageInfo(t, packages, "openssh-server")
+	assert.Equal(t, 1, info["epoch"])
+	assert.Equal(t, "9.6p1", info["upstream"])
+	assert.Equal(t, "3ubuntu13.8", info["revision"])
+}
+
+func TestGetPackageWithDifferentSourcePackage(t *testing.T) {
+	logger := hclog.New(&hclog.LoggerOptions{
+		Level:      hclog.Error,
+		JSONFormat: true,
+	})
+	packages, _ := getPackages(logger, "libssl3 3.0.2-0ubuntu1.16 openssl 3.0.2-0ubuntu1.16\n")
+
+	info := packageInfo(t, packages, "libssl3")
+	source, ok := info["source"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected source to be a map[string]any")
+	}
+	assert.Equal(t, "openssl", source["name"])
+	assert.Equal(t, "3.0.2-0ubuntu1.16", source["version"])
+}
+
+func TestGetPackages_IgnoresLinesWithWrongNumberOfFields(t *testing.T) {
+	logger := hclog.New(&hclog.LoggerOptions{
+		Level:      hclog.Error,
+		JSONFormat: true,
+	})
+	packages, _ := getPackages(logger, "mycoolpackage 1.2.3\n")
+
+	assert.Empty(t, packages)
+}
+
 func TestGetMultiplePackagesFromRealExamples(t *testing.T) {
 	// Setup
 	logger := hclog.New(&hclog.LoggerOptions{
@@ -128,43 +196,51 @@ func TestGetMultiplePackagesFromRealExamples(t *testing.T) {
 		JSONFormat: true,
 	})
 
-	packageStrings := []string{
-		"accountsservice 23.13.9-2ubuntu6",
-		"acl 2.3.2-1build1.1",
-		"adduser 3.137ubuntu1",
-		"adwaita-icon-theme 46.0-1",
-		"alsa-base 1.0.25+dfsg-0ubuntu7",
-		"amd64-microcode 3.20231019.1ubuntu2.1",
-		"apg 2.2.3.dfsg.1-5build3",
-		"g++ 4:13.2.0-7ubuntu1",
-		"g++-13-x86-64-linux-gnu 13.3.0-6ubuntu2~24.04",
-		"gir1.2-gmenu-3.0 3.36.0-1.1ubuntu3",
-		"gir1.2-upowerglib-1.0 1.90.3-1",
-		"heif-gdk-pixbuf 1.17.6-1ubuntu4.1",
-		"libatomic1 14.2.0-4ubuntu2~24.04",
-		"libatopology2t64 1.2.11-1build2",
-		"libatspi2.0-0t64 2.52.0-1build1",
-		"libattr1 1:2.5.2-1build1.1",
-		"libaudit-common 1:3.1.2-2.1build1.1",
-		"libcairo-gobject-perl 1.005-4build3",
-		"libdbusmenu-glib4 18.10.20180917~bzr492+repack1-3.1ubuntu5", // TODO: Should we have 20180917 as a patch?
-		"libjavascriptcoregtk-4.1-0 2.46.6-0ubuntu0.24.04.1",
-		"libplymouth5 24.004.60-1ubuntu7.1", // TODO: Should we definitely remove leading zeros on the 004?
-		"libplist-2.0-4 2.3.0-1~exp2build2",
-		"make 4.3-4.1build2",
-		"mongodb-mongosh 2.4.2",
-		"nano 7.2-2ubuntu0.1",
-		"nvidia-driver-550 550.144.03-0ubuntu1",
-		"openjdk-21-jre 21.0.6+7-1~24.04.1",
-		"openssh-server 1:9.6p1-3ubuntu13.8",
-		"printer-driver-foo2zjs 20200505dfsg0-2ubuntu6",
+	packageNamesAndVersions := []struct {
+		name    string
+		version string
+	}{
+		{"accountsservice", "23.13.9-2ubuntu6"},
+		{"acl", "2.3.2-1build1.1"},
+		{"adduser", "3.137ubuntu1"},
+		{"adwaita-icon-theme", "46.0-1"},
+		{"alsa-base", "1.0.25+dfsg-0ubuntu7"},
+		{"amd64-microcode", "3.20231019.1ubuntu2.1"},
+		{"apg", "2.2.3.dfsg.1-5build3"},
+		{"g++", "4:13.2.0-7ubuntu1"},
+		{"g++-13-x86-64-linux-gnu", "13.3.0-6ubuntu2~24.04"},
+		{"gir1.2-gmenu-3.0", "3.36.0-1.1ubuntu3"},
+		{"gir1.2-upowerglib-1.0", "1.90.3-1"},
+		{"heif-gdk-pixbuf", "1.17.6-1ubuntu4.1"},
+		{"libatomic1", "14.2.0-4ubuntu2~24.04"},
+		{"libatopology2t64", "1.2.11-1build2"},
+		{"libatspi2.0-0t64", "2.52.0-1build1"},
+		{"libattr1", "1:2.5.2-1build1.1"},
+		{"libaudit-common", "1:3.1.2-2.1build1.1"},
+		{"libcairo-gobject-perl", "1.005-4build3"},
+		{"libdbusmenu-glib4", "18.10.20180917~bzr492+repack1-3.1ubuntu5"}, // TODO: Should we have 20180917 as a patch?
+		{"libjavascriptcoregtk-4.1-0", "2.46.6-0ubuntu0.24.04.1"},
+		{"libplymouth5", "24.004.60-1ubuntu7.1"}, // TODO: Should we definitely remove leading zeros on the 004?
+		{"libplist-2.0-4", "2.3.0-1~exp2build2"},
+		{"make", "4.3-4.1build2"},
+		{"mongodb-mongosh", "2.4.2"},
+		{"nano", "7.2-2ubuntu0.1"},
+		{"nvidia-driver-550", "550.144.03-0ubuntu1"},
+		{"openjdk-21-jre", "21.0.6+7-1~24.04.1"},
+		{"openssh-server", "1:9.6p1-3ubuntu13.8"},
+		{"printer-driver-foo2zjs", "20200505dfsg0-2ubuntu6"},
+	}
+
+	packageLines := make([]string, len(packageNamesAndVersions))
+	for i, pkg := range packageNamesAndVersions {
+		packageLines[i] = selfSourced(pkg.name, pkg.version)
 	}
 
 	// Get the packages
-	packages, steps := getPackages(logger, strings.Join(packageStrings, "\n"))
+	packages, steps := getPackages(logger, strings.Join(packageLines, "\n"))
 
 	// Assertions
-	assert.Equal(t, len(packages), len(packageStrings))
+	assert.Equal(t, len(packages), len(packageLines))
 
 	// Check the correct packages are in the map
 	for expectedPkg, expectedVersion := range map[string]string{
@@ -198,9 +274,8 @@ func TestGetMultiplePackagesFromRealExamples(t *testing.T) {
 		"openssh-server":             "9.6.0",
 		"printer-driver-foo2zjs":     "20200505.0.0",
 	} {
-		assert.Contains(t, packages, expectedPkg)
-		version := packages[expectedPkg].(string)
-		assert.Equal(t, version, expectedVersion)
+		info := packageInfo(t, packages, expectedPkg)
+		assert.Equal(t, expectedVersion, info["version"])
 	}
 
 	assert.Len(t, steps, 1)
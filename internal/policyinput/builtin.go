@@ -0,0 +1,52 @@
+package policyinput
+
+import (
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+)
+
+// aptSatisfiesDecl declares apt.satisfies(pkg, constraint), a Rego builtin
+// policies can call directly, e.g.:
+//
+//	apt.satisfies(input.openssl, ">= 3.0.0-0ubuntu1").ok
+var aptSatisfiesDecl = &rego.Function{
+	Name: "apt.satisfies",
+	Decl: types.NewFunction(
+		types.Args(
+			types.NewObject(nil, types.NewDynamicProperty(types.S, types.A)),
+			types.S,
+		),
+		types.NewObject([]*types.StaticProperty{
+			types.NewStaticProperty("ok", types.B),
+			types.NewStaticProperty("reason", types.S),
+		}, nil),
+	),
+}
+
+func init() {
+	rego.RegisterBuiltin2(aptSatisfiesDecl, builtinAptSatisfies)
+}
+
+func builtinAptSatisfies(_ rego.BuiltinContext, pkgTerm, constraintTerm *ast.Term) (*ast.Term, error) {
+	var pkg map[string]any
+	if err := ast.As(pkgTerm.Value, &pkg); err != nil {
+		return nil, fmt.Errorf("apt.satisfies: pkg argument must be an object: %w", err)
+	}
+
+	var constraint string
+	if err := ast.As(constraintTerm.Value, &constraint); err != nil {
+		return nil, fmt.Errorf("apt.satisfies: constraint argument must be a string: %w", err)
+	}
+
+	ok, reason := Satisfies(pkg, constraint)
+
+	result, err := ast.InterfaceToValue(map[string]any{"ok": ok, "reason": reason})
+	if err != nil {
+		return nil, fmt.Errorf("apt.satisfies: error building result term: %w", err)
+	}
+
+	return ast.NewTerm(result), nil
+}
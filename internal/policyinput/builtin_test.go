@@ -0,0 +1,76 @@
+package policyinput
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// TestBuiltinAptSatisfies_ThroughRego drives the registered apt.satisfies
+// builtin through a real rego.Eval, with input shaped the way OPA actually
+// decodes it (JSON numbers, not Go ints), rather than calling Satisfies
+// directly. This is the path chunk0-6's original tests never exercised, and
+// it's the one real policies hit.
+func TestBuiltinAptSatisfies_ThroughRego(t *testing.T) {
+	ctx := context.Background()
+
+	input := map[string]any{
+		"pkg": map[string]any{
+			"version":  "1.0.0",
+			"epoch":    2,
+			"upstream": "1.0.0",
+			"revision": "1",
+		},
+	}
+
+	r := rego.New(
+		rego.Query("x = apt.satisfies(input.pkg, \">= 2:0.9.0-1\")"),
+		rego.Input(input),
+	)
+
+	rs, err := r.Eval(ctx)
+	if err != nil {
+		t.Fatalf("rego.Eval failed: %s", err)
+	}
+	if len(rs) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(rs))
+	}
+
+	result, ok := rs[0].Bindings["x"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected x to bind to an object, got %#v", rs[0].Bindings["x"])
+	}
+
+	if ok, _ := result["ok"].(bool); !ok {
+		t.Fatalf("expected epoch 2 package to satisfy >= 2:0.9.0-1, got %#v", result)
+	}
+}
+
+func TestBuiltinAptSatisfies_ThroughRego_EpochBlocksMatch(t *testing.T) {
+	ctx := context.Background()
+
+	input := map[string]any{
+		"pkg": map[string]any{
+			"version":  "1.0.0",
+			"epoch":    1,
+			"upstream": "1.0.0",
+			"revision": "1",
+		},
+	}
+
+	r := rego.New(
+		rego.Query("x = apt.satisfies(input.pkg, \">= 2:0.9.0-1\")"),
+		rego.Input(input),
+	)
+
+	rs, err := r.Eval(ctx)
+	if err != nil {
+		t.Fatalf("rego.Eval failed: %s", err)
+	}
+
+	result := rs[0].Bindings["x"].(map[string]interface{})
+	if ok, _ := result["ok"].(bool); ok {
+		t.Fatalf("expected epoch 1 package not to satisfy >= 2:0.9.0-1, got %#v", result)
+	}
+}
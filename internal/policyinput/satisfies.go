@@ -0,0 +1,117 @@
+// Package policyinput registers a constraint-matching Rego builtin,
+// apt.satisfies, for policyManager's Rego evaluation, so policies can compare
+// package versions using real dpkg ordering instead of string comparison.
+// The data passed to policy evaluation is not changed.
+package policyinput
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/compliance-framework/plugin-apt-versions/internal/debver"
+)
+
+// dpkgOperators maps a dpkg-native relational operator (as used in
+// Depends/Conflicts fields and .dsc/control files) to a predicate over the
+// result of debver.Compare(installed, reference).
+var dpkgOperators = map[string]func(cmp int) bool{
+	"<<": func(cmp int) bool { return cmp < 0 },
+	"<=": func(cmp int) bool { return cmp <= 0 },
+	"=":  func(cmp int) bool { return cmp == 0 },
+	">=": func(cmp int) bool { return cmp >= 0 },
+	">>": func(cmp int) bool { return cmp > 0 },
+}
+
+// dpkgConstraintPattern requires at least one space between the operator and
+// the version, matching the convention every real Depends/Conflicts field
+// uses ("libc6 (>= 2.17)"). Without that requirement, a semver range typed
+// without a space (">=1.0.0") would also match here and never reach
+// satisfiesSemverRange.
+var dpkgConstraintPattern = regexp.MustCompile(`^\s*(<<|<=|=|>=|>>)\s+(\S+)\s*$`)
+
+// Satisfies evaluates constraint against the version recorded in pkg (the
+// epoch/upstream/revision/version fields a PackageCollector writes into the
+// package map) and reports whether it matched, along with a human-readable
+// reason either way.
+//
+// constraint accepts two forms:
+//   - a dpkg-native relational operator followed by a version, e.g.
+//     ">= 1.2.3-1" or "<< 2:1.0"
+//   - a Masterminds-style semver range (">=1.2, <2.0", "~1.2.3", "^1.2"),
+//     evaluated against the package's normalised `x.y.z` version
+func Satisfies(pkg map[string]any, constraint string) (bool, string) {
+	if match := dpkgConstraintPattern.FindStringSubmatch(constraint); match != nil {
+		return satisfiesDpkgOperator(pkg, match[1], match[2])
+	}
+
+	return satisfiesSemverRange(pkg, constraint)
+}
+
+func satisfiesDpkgOperator(pkg map[string]any, op, reference string) (bool, string) {
+	installed := debver.Version{
+		Epoch:    toInt(pkg["epoch"]),
+		Upstream: toString(pkg["upstream"]),
+		Revision: toString(pkg["revision"]),
+	}
+
+	predicate, ok := dpkgOperators[op]
+	if !ok {
+		return false, fmt.Sprintf("unsupported dpkg operator %q", op)
+	}
+
+	cmp := debver.Compare(installed, debver.Parse(reference))
+	matched := predicate(cmp)
+
+	return matched, fmt.Sprintf("dpkg constraint %q %s %q", op+" "+reference, satisfiedWord(matched), reference)
+}
+
+func satisfiesSemverRange(pkg map[string]any, constraint string) (bool, string) {
+	installedVersion, err := semver.NewVersion(toString(pkg["version"]))
+	if err != nil {
+		return false, fmt.Sprintf("could not parse installed version %q as semver: %s", pkg["version"], err)
+	}
+
+	semRange, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Sprintf("could not parse %q as a semver constraint: %s", constraint, err)
+	}
+
+	matched := semRange.Check(installedVersion)
+	return matched, fmt.Sprintf("semver constraint %q %s by installed version %s", constraint, satisfiedWord(matched), installedVersion)
+}
+
+func satisfiedWord(matched bool) string {
+	if matched {
+		return "satisfied"
+	}
+	return "not satisfied"
+}
+
+// toInt unwraps an epoch value, which may arrive as a native Go int (when
+// Satisfies is called directly) or as a float64/json.Number (when pkg was
+// decoded from an OPA ast.Object via ast.As, which round-trips through
+// JSON-shaped values).
+func toInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	case json.Number:
+		i, _ := n.Int64()
+		return int(i)
+	}
+	return 0
+}
+
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
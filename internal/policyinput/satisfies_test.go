@@ -0,0 +1,123 @@
+package policyinput
+
+import "testing"
+
+func pkgWith(version string, epoch int, upstream, revision string) map[string]any {
+	return map[string]any{
+		"version":  version,
+		"epoch":    epoch,
+		"upstream": upstream,
+		"revision": revision,
+	}
+}
+
+func TestSatisfies_DpkgOperators(t *testing.T) {
+	pkg := pkgWith("3.0.2", 0, "3.0.2", "0ubuntu1")
+
+	tests := []struct {
+		constraint string
+		want       bool
+	}{
+		{">= 3.0.2-0ubuntu1", true},
+		{">= 3.0.3-0ubuntu1", false},
+		{"<< 3.0.3-0ubuntu1", true},
+		{"<< 3.0.2-0ubuntu1", false},
+		{"<= 3.0.2-0ubuntu1", true},
+		{"= 3.0.2-0ubuntu1", true},
+		{">> 3.0.2-0ubuntu1", false},
+		{">> 3.0.1-1", true},
+	}
+
+	for _, tt := range tests {
+		ok, reason := Satisfies(pkg, tt.constraint)
+		if ok != tt.want {
+			t.Errorf("Satisfies(%q) = %v (%s), want %v", tt.constraint, ok, reason, tt.want)
+		}
+		if reason == "" {
+			t.Errorf("Satisfies(%q) returned empty reason", tt.constraint)
+		}
+	}
+}
+
+func TestSatisfies_DpkgOperator_RespectsEpoch(t *testing.T) {
+	pkg := pkgWith("2.0.0", 2, "2.0.0", "1")
+
+	ok, _ := Satisfies(pkg, ">= 1:9.0.0-1")
+	if !ok {
+		t.Fatalf("expected epoch 2 package to satisfy >= 1:9.0.0-1 regardless of the lower upstream version")
+	}
+
+	ok, _ = Satisfies(pkg, "<< 1:9.0.0-1")
+	if ok {
+		t.Fatalf("expected epoch 2 package not to satisfy << 1:9.0.0-1")
+	}
+}
+
+func TestSatisfies_SemverRanges(t *testing.T) {
+	pkg := pkgWith("1.4.2", 0, "1.4.2", "1")
+
+	tests := []struct {
+		constraint string
+		want       bool
+	}{
+		{">=1.2, <2.0", true},
+		{">=1.5", false},
+		{"^1.2", true},
+		{"^2.0", false},
+		{"~1.4.0", true},
+		{"~1.3.0", false},
+	}
+
+	for _, tt := range tests {
+		ok, reason := Satisfies(pkg, tt.constraint)
+		if ok != tt.want {
+			t.Errorf("Satisfies(%q) = %v (%s), want %v", tt.constraint, ok, reason, tt.want)
+		}
+	}
+}
+
+func TestSatisfies_SemverRange_InvalidInstalledVersion(t *testing.T) {
+	pkg := pkgWith("not-a-version", 0, "not-a-version", "")
+
+	ok, reason := Satisfies(pkg, ">=1.0.0")
+	if ok {
+		t.Fatalf("expected false for unparseable installed version")
+	}
+	if reason == "" {
+		t.Fatalf("expected a reason explaining the parse failure")
+	}
+}
+
+func TestSatisfies_DpkgOperator_EpochAsJSONNumber(t *testing.T) {
+	// Mirrors a package map whose epoch has round-tripped through JSON-shaped
+	// values: the epoch arrives as a decoded JSON number (here a float64),
+	// not as a native Go int.
+	pkg := map[string]any{
+		"version":  "2.0.0",
+		"epoch":    float64(2),
+		"upstream": "2.0.0",
+		"revision": "1",
+	}
+
+	ok, _ := Satisfies(pkg, ">= 1:9.0.0-1")
+	if !ok {
+		t.Fatalf("expected epoch 2 (float64) package to satisfy >= 1:9.0.0-1 regardless of the lower upstream version")
+	}
+
+	ok, _ = Satisfies(pkg, "<< 1:9.0.0-1")
+	if ok {
+		t.Fatalf("expected epoch 2 (float64) package not to satisfy << 1:9.0.0-1")
+	}
+}
+
+func TestSatisfies_InvalidSemverConstraint(t *testing.T) {
+	pkg := pkgWith("1.0.0", 0, "1.0.0", "")
+
+	ok, reason := Satisfies(pkg, "not a constraint")
+	if ok {
+		t.Fatalf("expected false for an unparseable constraint")
+	}
+	if reason == "" {
+		t.Fatalf("expected a reason explaining the parse failure")
+	}
+}
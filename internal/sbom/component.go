@@ -0,0 +1,111 @@
+// Package sbom converts the package map collected by an
+// internal.PackageCollector into standard machine-readable inventory
+// documents (CycloneDX and SPDX) that compliance auditors can consume
+// alongside the plugin's policy findings.
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// purlTypes maps the package manager names used by internal.DetectCollector
+// to their package-url (https://github.com/package-url/purl-spec) type.
+var purlTypes = map[string]string{
+	"dpkg": "deb",
+	"rpm":  "rpm",
+	"apk":  "apk",
+}
+
+// Component is a single package normalised into the fields every supported
+// SBOM format needs: a name, a version, a purl identifying it uniquely, and
+// the supplier of the package (the distro it was built for).
+type Component struct {
+	Name     string
+	Version  string
+	Purl     string
+	Supplier string
+}
+
+// BuildComponents converts the package map collected by a
+// internal.PackageCollector (keyed by package name) into a sorted slice of
+// Components, ready to hand to GenerateCycloneDX or GenerateSPDX.
+func BuildComponents(packages map[string]any, manager string, distro Distro) []Component {
+	components := make([]Component, 0, len(packages))
+
+	for name, raw := range packages {
+		info, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		version := dpkgVersion(info)
+		components = append(components, Component{
+			Name:     name,
+			Version:  version,
+			Purl:     buildPurl(manager, name, version, info, distro),
+			Supplier: distro.PrettyName,
+		})
+	}
+
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+
+	return components
+}
+
+// dpkgVersion reconstructs the full "epoch:upstream-revision" version
+// string from the epoch/upstream/revision fields a collector stores
+// alongside the normalised SemVer triple.
+func dpkgVersion(info map[string]any) string {
+	upstream, _ := info["upstream"].(string)
+	if upstream == "" {
+		upstream, _ = info["version"].(string)
+	}
+
+	version := upstream
+	if epoch, ok := info["epoch"].(int); ok && epoch != 0 {
+		version = fmt.Sprintf("%d:%s", epoch, version)
+	}
+
+	revision, _ := info["revision"].(string)
+	if revision == "" {
+		revision, _ = info["release"].(string)
+	}
+	if revision != "" {
+		version = fmt.Sprintf("%s-%s", version, revision)
+	}
+
+	return version
+}
+
+func buildPurl(manager, name, version string, info map[string]any, distro Distro) string {
+	purlType := purlTypes[manager]
+	if purlType == "" {
+		purlType = manager
+	}
+
+	namespace := distro.ID
+	if namespace == "" {
+		namespace = "unknown"
+	}
+
+	qs := url.Values{}
+	if arch, _ := info["arch"].(string); arch != "" {
+		qs.Set("arch", arch)
+	}
+	if distro.ID != "" {
+		distroTag := distro.ID
+		if distro.VersionID != "" {
+			distroTag = fmt.Sprintf("%s-%s", distro.ID, distro.VersionID)
+		}
+		qs.Set("distro", distroTag)
+	}
+
+	purl := fmt.Sprintf("pkg:%s/%s/%s@%s", purlType, namespace, name, version)
+	if encoded := qs.Encode(); encoded != "" {
+		purl = fmt.Sprintf("%s?%s", purl, encoded)
+	}
+
+	return purl
+}
@@ -0,0 +1,50 @@
+package sbom
+
+import "encoding/json"
+
+// CycloneDXDocument is a minimal CycloneDX 1.5 BOM
+// (https://cyclonedx.org/docs/1.5/json/) carrying just the fields this
+// plugin populates: one component per installed package.
+type CycloneDXDocument struct {
+	BomFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXComponent struct {
+	Type     string                 `json:"type"`
+	Name     string                 `json:"name"`
+	Version  string                 `json:"version"`
+	Purl     string                 `json:"purl"`
+	Supplier *CycloneDXOrganization `json:"supplier,omitempty"`
+}
+
+type CycloneDXOrganization struct {
+	Name string `json:"name"`
+}
+
+// GenerateCycloneDX converts components into a CycloneDX 1.5 JSON document.
+func GenerateCycloneDX(components []Component) ([]byte, error) {
+	doc := CycloneDXDocument{
+		BomFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Components:  make([]CycloneDXComponent, 0, len(components)),
+	}
+
+	for _, c := range components {
+		component := CycloneDXComponent{
+			Type:    "library",
+			Name:    c.Name,
+			Version: c.Version,
+			Purl:    c.Purl,
+		}
+		if c.Supplier != "" {
+			component.Supplier = &CycloneDXOrganization{Name: c.Supplier}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,43 @@
+package sbom
+
+import (
+	"os"
+	"strings"
+)
+
+// Distro is the subset of /etc/os-release fields needed to build purls and
+// attribute a supplier for the packages collected on this host.
+type Distro struct {
+	ID         string
+	VersionID  string
+	PrettyName string
+}
+
+// DetectDistro reads /etc/os-release, returning the zero Distro if it
+// cannot be read (e.g. in a container image stripped of it).
+func DetectDistro() Distro {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return Distro{}
+	}
+
+	var distro Distro
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, "\"")
+
+		switch key {
+		case "ID":
+			distro.ID = value
+		case "VERSION_ID":
+			distro.VersionID = value
+		case "PRETTY_NAME":
+			distro.PrettyName = value
+		}
+	}
+
+	return distro
+}
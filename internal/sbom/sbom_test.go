@@ -0,0 +1,63 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func samplePackages() map[string]any {
+	return map[string]any{
+		"openssl": map[string]any{
+			"version":  "3.0.2",
+			"epoch":    0,
+			"upstream": "3.0.2",
+			"revision": "0ubuntu1",
+			"arch":     "amd64",
+		},
+	}
+}
+
+func TestBuildComponents_PurlAndVersion(t *testing.T) {
+	distro := Distro{ID: "ubuntu", VersionID: "24.04", PrettyName: "Ubuntu 24.04.1 LTS"}
+	components := BuildComponents(samplePackages(), "dpkg", distro)
+
+	if len(components) != 1 {
+		t.Fatalf("expected 1 component, got %d", len(components))
+	}
+
+	c := components[0]
+	assert.Equal(t, "openssl", c.Name)
+	assert.Equal(t, "3.0.2-0ubuntu1", c.Version)
+	assert.Equal(t, "pkg:deb/ubuntu/openssl@3.0.2-0ubuntu1?arch=amd64&distro=ubuntu-24.04", c.Purl)
+	assert.Equal(t, "Ubuntu 24.04.1 LTS", c.Supplier)
+}
+
+func TestGenerateCycloneDX(t *testing.T) {
+	components := BuildComponents(samplePackages(), "dpkg", Distro{ID: "ubuntu", VersionID: "24.04"})
+	data, err := GenerateCycloneDX(components)
+	assert.NoError(t, err)
+
+	var doc CycloneDXDocument
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "CycloneDX", doc.BomFormat)
+	assert.Equal(t, "1.5", doc.SpecVersion)
+	assert.Len(t, doc.Components, 1)
+	assert.Equal(t, "openssl", doc.Components[0].Name)
+	assert.Contains(t, doc.Components[0].Purl, "pkg:deb/ubuntu/openssl")
+}
+
+func TestGenerateSPDX(t *testing.T) {
+	components := BuildComponents(samplePackages(), "dpkg", Distro{ID: "ubuntu", VersionID: "24.04"})
+	data, err := GenerateSPDX(components)
+	assert.NoError(t, err)
+
+	var doc SPDXDocument
+	assert.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Len(t, doc.Packages, 1)
+	assert.Equal(t, "openssl", doc.Packages[0].Name)
+	assert.Len(t, doc.Packages[0].ExternalRefs, 1)
+	assert.Equal(t, "purl", doc.Packages[0].ExternalRefs[0].ReferenceType)
+}
@@ -0,0 +1,83 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SPDXDocument is a minimal SPDX 2.3 JSON document
+// (https://spdx.github.io/spdx-spec/v2.3/) carrying just the fields this
+// plugin populates: one package per installed package.
+type SPDXDocument struct {
+	SPDXVersion string        `json:"spdxVersion"`
+	DataLicense string        `json:"dataLicense"`
+	SPDXID      string        `json:"SPDXID"`
+	Name        string        `json:"name"`
+	Packages    []SPDXPackage `json:"packages"`
+}
+
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo"`
+	Supplier         string            `json:"supplier,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs"`
+	DownloadLocation string            `json:"downloadLocation"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// GenerateSPDX converts components into an SPDX 2.3 JSON document.
+func GenerateSPDX(components []Component) ([]byte, error) {
+	doc := SPDXDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        "installed-packages",
+		Packages:    make([]SPDXPackage, 0, len(components)),
+	}
+
+	for _, c := range components {
+		supplier := ""
+		if c.Supplier != "" {
+			supplier = fmt.Sprintf("Organization: %s", c.Supplier)
+		}
+
+		doc.Packages = append(doc.Packages, SPDXPackage{
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%s", spdxRefSafe(c.Name)),
+			Name:             c.Name,
+			VersionInfo:      c.Version,
+			Supplier:         supplier,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []SPDXExternalRef{
+				{
+					ReferenceCategory: "PACKAGE-MANAGER",
+					ReferenceType:     "purl",
+					ReferenceLocator:  c.Purl,
+				},
+			},
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// spdxRefSafe replaces characters the SPDX ID grammar disallows (anything
+// outside [A-Za-z0-9.-]) with a hyphen.
+func spdxRefSafe(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
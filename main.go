@@ -2,21 +2,42 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	policyManager "github.com/compliance-framework/agent/policy-manager"
 	"github.com/compliance-framework/agent/runner"
 	"github.com/compliance-framework/agent/runner/proto"
 	"github.com/compliance-framework/plugin-apt-versions/internal"
+	"github.com/compliance-framework/plugin-apt-versions/internal/advisory"
+	// policyinput registers the apt.satisfies(pkg, constraint) Rego builtin
+	// with OPA as a side effect of being imported.
+	_ "github.com/compliance-framework/plugin-apt-versions/internal/policyinput"
+	"github.com/compliance-framework/plugin-apt-versions/internal/sbom"
 	"github.com/hashicorp/go-hclog"
 	goplugin "github.com/hashicorp/go-plugin"
 	"os"
 	"slices"
 )
 
+// defaultSBOMFormat is used when sbom_output is configured without an
+// explicit sbom_format.
+const defaultSBOMFormat = "cyclonedx"
+
 type AptVersion struct {
 	logger hclog.Logger
 	config map[string]string
+
+	versionCollector internal.PackageCollector
+	packageManager   string
+	data             internal.PackageVersions
+	collectSteps     []*proto.Step
+
+	advisoryClient *advisory.Client
+
+	sbomEnabled bool
+	sbomOutput  string
+	sbomFormat  string
 }
 
 // Configure, and Eval are called at different times during the plugin execution lifecycle,
@@ -50,33 +71,101 @@ func (l *AptVersion) Configure(req *proto.ConfigureRequest) (*proto.ConfigureRes
 	// This will likely only be called once on plugin startup, which may then run for an extended period of time.
 
 	l.config = req.GetConfig()
+
+	collector, packageManager, err := internal.DetectCollector(l.logger, l.config["package_manager"])
+	if err != nil {
+		return nil, fmt.Errorf("error detecting host package manager: %w", err)
+	}
+	l.versionCollector = collector
+	l.packageManager = packageManager
+	l.advisoryClient = advisory.NewClient(l.logger, advisory.ConfigFromMap(l.config))
+
+	l.sbomOutput = l.config["sbom_output"]
+	l.sbomEnabled = l.sbomOutput != "" || l.config["sbom_format"] != ""
+	l.sbomFormat = l.config["sbom_format"]
+	if l.sbomFormat == "" {
+		l.sbomFormat = defaultSBOMFormat
+	}
+
 	return &proto.ConfigureResponse{}, nil
 }
 
+// PrepareForEval is an optional pre-warming hook: callers that want the
+// (potentially slow) host collection to happen before the first Eval can
+// call it directly. It is NOT part of the runner.Runner interface the real
+// agent drives this plugin through, which only defines Configure and Eval,
+// so Eval must not assume it has been called.
+func (l *AptVersion) PrepareForEval(_ *proto.PrepareForEvalRequest) (*proto.PrepareForEvalResponse, error) {
+	if err := l.collectPackages(); err != nil {
+		return nil, err
+	}
+	return &proto.PrepareForEvalResponse{}, nil
+}
+
+// collectPackages gathers the installed package data if it hasn't already
+// been collected this run, so Eval behaves correctly whether or not
+// PrepareForEval was called ahead of it.
+func (l *AptVersion) collectPackages() error {
+	if l.data != nil {
+		return nil
+	}
+
+	data, rawOutput, err := l.versionCollector.GetInstalledPackages()
+	l.logger.Trace(fmt.Sprintf("Packages output: %s", rawOutput))
+	if err != nil {
+		return fmt.Errorf("error getting installed packages: %w", err)
+	}
+
+	l.data = data
+	l.collectSteps = []*proto.Step{
+		{
+			Title:       "Get installed packages",
+			Description: fmt.Sprintf("Get the list of installed package names and versions on the host using the %s package manager.", l.packageManager),
+			Remarks:     internal.StringAddressed(fmt.Sprintf("Collected %d installed packages", len(data))),
+		},
+	}
+
+	return nil
+}
+
 func (l *AptVersion) Eval(request *proto.EvalRequest, apiHelper runner.ApiHelper) (*proto.EvalResponse, error) {
 	ctx := context.TODO()
 
-	activities := make([]*proto.Activity, 0)
+	if err := l.collectPackages(); err != nil {
+		return &proto.EvalResponse{Status: proto.ExecutionStatus_FAILURE}, err
+	}
 
-	data, getInstalledPackagesSteps, err := internal.GetInstalledPackages(l.logger)
-	l.logger.Trace(fmt.Sprintf("Packages output: %s", data))
-	if err != nil {
-		return nil, fmt.Errorf("error getting installed packages: %w", err)
+	activities := []*proto.Activity{
+		{
+			Title:       "Collect OS packages installed",
+			Description: "Collect OS packages installed on the host machine, and prepare collected data for validation in policy engine",
+			Steps:       l.collectSteps,
+		},
 	}
 
-	activities = append(activities, &proto.Activity{
-		Title:       "Collect OS packages installed",
-		Description: "Collect OS packages installed on the host machine, and prepare collected data for validation in policy engine",
-		Steps:       getInstalledPackagesSteps,
-	})
+	if l.sbomEnabled {
+		sbomStep, sbomLink, err := l.writeSBOM()
+		if err != nil {
+			l.logger.Error("Failed to generate SBOM", "error", err)
+		} else {
+			activities = append(activities, &proto.Activity{
+				Title:       "Generate SBOM",
+				Description: "Convert the collected package list into a standard machine-readable inventory document for compliance auditors.",
+				Steps:       []*proto.Step{sbomStep},
+				Links:       []*proto.Link{sbomLink},
+			})
+		}
+	}
 
-	observations, findings, err := l.evaluatePolicies(ctx, activities, data, request)
+	observations, findings, err := l.evaluatePolicies(ctx, activities, l.data, request)
 	if err != nil {
 		return &proto.EvalResponse{
 			Status: proto.ExecutionStatus_FAILURE,
 		}, err
 	}
 
+	findings = append(findings, l.advisoryFindings()...)
+
 	if err = apiHelper.CreateObservations(ctx, observations); err != nil {
 		l.logger.Error("Failed to send observations", "error", err)
 		return &proto.EvalResponse{
@@ -124,6 +213,11 @@ func (l *AptVersion) evaluatePolicies(ctx context.Context, activities []*proto.A
 					Value:   hostname,
 					Remarks: internal.StringAddressed("The local hostname of the machine where the plugin has been executed"),
 				},
+				{
+					Name:    "package_manager",
+					Value:   l.packageManager,
+					Remarks: internal.StringAddressed("The host package manager the installed package list was collected from (dpkg, rpm, or apk)."),
+				},
 			},
 		},
 	}
@@ -185,6 +279,101 @@ func (l *AptVersion) evaluatePolicies(ctx context.Context, activities []*proto.A
 	return observations, findings, nil
 }
 
+// advisoryFindings cross-references the collected package data against the
+// plugin's configured CVE/USN feed, returning an additional proto.Finding
+// for every advisory that has not yet been fixed on this host. Any error
+// fetching or parsing the feed (e.g. the host is offline and no
+// offline_advisory_file was configured) is logged and treated as "no
+// advisory findings this run" rather than failing the whole evaluation.
+func (l *AptVersion) advisoryFindings() []*proto.Finding {
+	if l.advisoryClient == nil {
+		return nil
+	}
+
+	advisories, err := l.advisoryClient.Advisories(advisory.DetectCodename())
+	if err != nil {
+		l.logger.Warn("failed to load advisory feed, skipping vulnerability findings", "error", err)
+		return nil
+	}
+
+	unfixed := advisory.Unfixed(l.data, advisories)
+	findings := make([]*proto.Finding, 0, len(unfixed))
+	for _, adv := range unfixed {
+		findings = append(findings, &proto.Finding{
+			Title:       fmt.Sprintf("%s is affected by %s", adv.Package, adv.CVE),
+			Description: fmt.Sprintf("Installed package %s has not been updated to the version (%s) that resolves %s.", adv.Package, adv.FixedVersion, adv.CVE),
+			Status:      proto.FindingStatus_OPEN.String(),
+			Props: []*proto.Property{
+				{Name: "cve", Value: adv.CVE, Remarks: internal.StringAddressed("The CVE identifier(s) associated with this advisory.")},
+				{Name: "usn", Value: adv.USN, Remarks: internal.StringAddressed("The Ubuntu Security Notice identifier associated with this advisory, if sourced from the ubuntu-oval feed.")},
+				{Name: "severity", Value: adv.Severity, Remarks: internal.StringAddressed("The upstream-reported severity of this advisory.")},
+				{Name: "fixed_version", Value: adv.FixedVersion, Remarks: internal.StringAddressed("The version of the package that resolves this advisory.")},
+			},
+		})
+	}
+
+	return findings
+}
+
+// sbomMediaTypes maps an sbom_format to the media type recorded on the Link
+// attached to the "Generate SBOM" activity.
+var sbomMediaTypes = map[string]string{
+	"cyclonedx": "application/vnd.cyclonedx+json",
+	"spdx":      "application/spdx+json",
+}
+
+// writeSBOM converts the collected package data into the configured SBOM
+// format (CycloneDX by default, or SPDX if sbom_format=spdx), returning a
+// Step describing the conversion and a Link for the "Generate SBOM"
+// activity. Writing the document to sbom_output is optional: sbom_format
+// alone is enough to have it generated and attached as evidence, the Link
+// then carrying the document itself as a base64 data URI. When sbom_output
+// is set, the document is written there and the Link is a file:// URI
+// pointing at it.
+func (l *AptVersion) writeSBOM() (*proto.Step, *proto.Link, error) {
+	distro := sbom.DetectDistro()
+	components := sbom.BuildComponents(l.data, l.packageManager, distro)
+
+	var document []byte
+	var err error
+	switch l.sbomFormat {
+	case "spdx":
+		document, err = sbom.GenerateSPDX(components)
+	case "cyclonedx":
+		document, err = sbom.GenerateCycloneDX(components)
+	default:
+		return nil, nil, fmt.Errorf("unknown sbom_format %q, expected one of cyclonedx, spdx", l.sbomFormat)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating %s SBOM: %w", l.sbomFormat, err)
+	}
+
+	href := fmt.Sprintf("data:%s;base64,%s", sbomMediaTypes[l.sbomFormat], base64.StdEncoding.EncodeToString(document))
+	remarks := fmt.Sprintf("Generated %d components", len(components))
+
+	if l.sbomOutput != "" {
+		if err := os.WriteFile(l.sbomOutput, document, 0o644); err != nil {
+			return nil, nil, fmt.Errorf("error writing SBOM to %s: %w", l.sbomOutput, err)
+		}
+		href = "file://" + l.sbomOutput
+		remarks = fmt.Sprintf("Wrote %d components to %s", len(components), l.sbomOutput)
+	}
+
+	step := &proto.Step{
+		Title:       fmt.Sprintf("Generated %s SBOM", l.sbomFormat),
+		Description: fmt.Sprintf("Converted the collected package list into a %s document covering every installed package, for consumption by compliance auditors.", l.sbomFormat),
+		Remarks:     internal.StringAddressed(remarks),
+	}
+	link := &proto.Link{
+		Href:      href,
+		Rel:       internal.StringAddressed("evidence"),
+		MediaType: internal.StringAddressed(sbomMediaTypes[l.sbomFormat]),
+		Text:      internal.StringAddressed(fmt.Sprintf("%s SBOM", l.sbomFormat)),
+	}
+
+	return step, link, nil
+}
+
 func main() {
 	logger := hclog.New(&hclog.LoggerOptions{
 		Level:      hclog.Debug,
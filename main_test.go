@@ -1,11 +1,13 @@
 package main
 
 import (
+	"strings"
+	"testing"
+
 	"github.com/compliance-framework/agent/runner/proto"
 	"github.com/compliance-framework/plugin-apt-versions/internal"
 	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 type testVersionCollector struct {
@@ -78,3 +80,31 @@ func TestAptVersion_Eval(t *testing.T) {
 		assert.Equal(t, proto.FindingStatus_MITIGATED.String(), resp.Findings[0].Status)
 	})
 }
+
+func TestAptVersion_Configure_SBOMEnabledBySbomFormatAlone(t *testing.T) {
+	plugin := AptVersion{logger: hclog.NewNullLogger()}
+	_, err := plugin.Configure(&proto.ConfigureRequest{
+		Config: map[string]string{
+			"package_manager": "dpkg",
+			"sbom_format":     "spdx",
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, plugin.sbomEnabled, "sbom_format alone should enable SBOM generation, with no sbom_output required")
+	assert.Empty(t, plugin.sbomOutput)
+}
+
+func TestAptVersion_WriteSBOM_AttachesDataURILinkWithoutSbomOutput(t *testing.T) {
+	plugin := AptVersion{
+		logger:           hclog.NewNullLogger(),
+		packageManager:   "dpkg",
+		sbomFormat:       "cyclonedx",
+		versionCollector: &testVersionCollector{},
+	}
+	_, err := plugin.PrepareForEval(&proto.PrepareForEvalRequest{})
+	assert.NoError(t, err)
+
+	_, link, err := plugin.writeSBOM()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link.Href, "data:application/vnd.cyclonedx+json;base64,"))
+}